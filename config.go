@@ -0,0 +1,223 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"os"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/lxn/walk"
+	. "github.com/lxn/walk/declarative"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const configFile = "config.json"
+
+// configEnvelope is the on-disk shape of config.json once it's encrypted:
+// a marker plus the ciphertext, so loadConfigs can tell an encrypted file
+// from a legacy plaintext one and migrate it.
+type configEnvelope struct {
+	Encrypted bool   `json:"encrypted"`
+	Salt      string `json:"salt,omitempty"`
+	Data      string `json:"data"`
+}
+
+// masterPassphrase is cached for the life of the process once the user has
+// entered it, so they're only prompted once per run.
+var masterPassphrase string
+
+// loadConfigs reads config.json. Files written by an encryption-aware
+// version of the app are decrypted transparently; a plaintext file from an
+// older version is read as-is and reported via migrated so the caller can
+// re-save it encrypted.
+func loadConfigs() (configs map[string]*EtcdConfig, migrated bool, err error) {
+	f, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]*EtcdConfig{}, false, nil
+		}
+		return nil, false, err
+	}
+
+	var env configEnvelope
+	if err := jsoniter.Unmarshal(f, &env); err != nil || !env.Encrypted {
+		configs := map[string]*EtcdConfig{}
+		if err := jsoniter.Unmarshal(f, &configs); err != nil {
+			return nil, false, err
+		}
+		return configs, true, nil
+	}
+
+	plain, err := decryptEnvelope(env)
+	if err != nil {
+		return nil, false, err
+	}
+	configs = map[string]*EtcdConfig{}
+	if err := jsoniter.Unmarshal(plain, &configs); err != nil {
+		return nil, false, err
+	}
+	return configs, false, nil
+}
+
+// saveConfigs writes configs to config.json encrypted at rest: DPAPI when
+// available (Windows), otherwise AES-256-GCM with a key derived from a
+// user-supplied master passphrase via PBKDF2.
+func saveConfigs(configs map[string]*EtcdConfig) error {
+	plain, err := jsoniter.Marshal(configs)
+	if err != nil {
+		return err
+	}
+	env, err := encryptEnvelope(plain)
+	if err != nil {
+		return err
+	}
+	out, err := jsoniter.Marshal(env)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(configFile, out, 0600)
+}
+
+func encryptEnvelope(plain []byte) (configEnvelope, error) {
+	if cipherText, err := dpapiProtect(plain); err == nil {
+		return configEnvelope{Encrypted: true, Data: base64.StdEncoding.EncodeToString(cipherText)}, nil
+	}
+	return encryptWithPassphrase(plain)
+}
+
+// encryptWithPassphrase is encryptEnvelope's non-DPAPI path: AES-256-GCM
+// keyed by PBKDF2 over masterPassphrase with a fresh random salt.
+func encryptWithPassphrase(plain []byte) (configEnvelope, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return configEnvelope{}, err
+	}
+	gcm, err := passphraseGCM(salt)
+	if err != nil {
+		return configEnvelope{}, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return configEnvelope{}, err
+	}
+	cipherText := gcm.Seal(nonce, nonce, plain, nil)
+	return configEnvelope{
+		Encrypted: true,
+		Salt:      base64.StdEncoding.EncodeToString(salt),
+		Data:      base64.StdEncoding.EncodeToString(cipherText),
+	}, nil
+}
+
+func decryptEnvelope(env configEnvelope) ([]byte, error) {
+	cipherText, err := base64.StdEncoding.DecodeString(env.Data)
+	if err != nil {
+		return nil, err
+	}
+	if env.Salt == "" {
+		return dpapiUnprotect(cipherText)
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(env.Salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := passphraseGCM(salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(cipherText) < gcm.NonceSize() {
+		return nil, errors.New("config: ciphertext too short")
+	}
+	nonce, cipherText := cipherText[:gcm.NonceSize()], cipherText[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, cipherText, nil)
+}
+
+func passphraseGCM(salt []byte) (cipher.AEAD, error) {
+	if masterPassphrase == "" {
+		passphrase, err := promptMasterPassphrase()
+		if err != nil {
+			return nil, err
+		}
+		masterPassphrase = passphrase
+	}
+	key := pbkdf2.Key([]byte(masterPassphrase), salt, 100000, 32, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// promptMasterPassphrase is the fallback for platforms without DPAPI: the
+// passphrase it collects becomes the PBKDF2 input for config.json's key. A
+// non-empty passphrase is required, and cancelling the dialog is reported
+// as an error rather than silently returning "" - an empty passphrase would
+// otherwise defeat the point of encrypting config.json.
+func promptMasterPassphrase() (string, error) {
+	type passphraseInput struct {
+		Passphrase string
+	}
+	input := &passphraseInput{}
+
+	var dlg *walk.Dialog
+	var db *walk.DataBinder
+	var okPB, cancelPB *walk.PushButton
+
+	var owner walk.Form
+	if mainWindow != nil {
+		owner = mainWindow
+	}
+
+	if err := (Dialog{
+		AssignTo:      &dlg,
+		Title:         "Master Passphrase",
+		DefaultButton: &okPB,
+		CancelButton:  &cancelPB,
+		MinSize:       Size{300, 120},
+		DataBinder: DataBinder{
+			AssignTo:       &db,
+			DataSource:     input,
+			ErrorPresenter: ToolTipErrorPresenter{},
+		},
+		Layout: VBox{},
+		Children: []Widget{
+			Label{Text: "Enter the master passphrase protecting config.json:"},
+			LineEdit{PasswordMode: true, Text: Bind("Passphrase", EditRequired{})},
+			Composite{
+				Layout: HBox{MarginsZero: true},
+				Children: []Widget{
+					HSpacer{},
+					PushButton{
+						AssignTo: &okPB,
+						Text:     "OK",
+						OnClicked: func() {
+							if err := db.Submit(); err != nil {
+								zap.L().Error("Error", zap.Error(err))
+								return
+							}
+							dlg.Accept()
+						},
+					},
+					PushButton{
+						AssignTo: &cancelPB,
+						Text:     "Cancel",
+						OnClicked: func() { dlg.Cancel() },
+					},
+				},
+			},
+		},
+	}.Create(owner)); err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		return "", err
+	}
+	if dlg.Run() != walk.DlgCmdOK {
+		return "", errors.New("config: master passphrase entry was cancelled")
+	}
+	return input.Passphrase, nil
+}
@@ -0,0 +1,17 @@
+// +build !windows
+
+package main
+
+import "errors"
+
+// errDPAPIUnavailable is returned on platforms without CryptProtectData, so
+// saveConfigs/loadConfigs fall back to PBKDF2-derived passphrase encryption.
+var errDPAPIUnavailable = errors.New("dpapi: not available on this platform")
+
+func dpapiProtect(data []byte) ([]byte, error) {
+	return nil, errDPAPIUnavailable
+}
+
+func dpapiUnprotect(data []byte) ([]byte, error) {
+	return nil, errDPAPIUnavailable
+}
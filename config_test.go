@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// withMasterPassphrase sets masterPassphrase for the duration of a test so
+// passphraseGCM never falls through to promptMasterPassphrase's dialog.
+func withMasterPassphrase(t *testing.T, passphrase string) {
+	t.Helper()
+	old := masterPassphrase
+	masterPassphrase = passphrase
+	t.Cleanup(func() { masterPassphrase = old })
+}
+
+func TestEncryptDecryptEnvelopeRoundTrip(t *testing.T) {
+	withMasterPassphrase(t, "correct horse battery staple")
+
+	plain := []byte(`{"prod":{"Name":"prod","Endpoint":"http://127.0.0.1:2379"}}`)
+
+	env, err := encryptWithPassphrase(plain)
+	if err != nil {
+		t.Fatalf("encryptWithPassphrase: %v", err)
+	}
+	if !env.Encrypted {
+		t.Fatal("encryptWithPassphrase: env.Encrypted = false, want true")
+	}
+	if env.Salt == "" {
+		t.Fatal("encryptWithPassphrase: env.Salt is empty, want a per-encryption PBKDF2 salt")
+	}
+
+	got, err := decryptEnvelope(env)
+	if err != nil {
+		t.Fatalf("decryptEnvelope: %v", err)
+	}
+	if !bytes.Equal(got, plain) {
+		t.Fatalf("decryptEnvelope round-trip = %q, want %q", got, plain)
+	}
+}
+
+func TestDecryptEnvelopeWrongPassphraseFails(t *testing.T) {
+	withMasterPassphrase(t, "correct horse battery staple")
+	env, err := encryptWithPassphrase([]byte(`{}`))
+	if err != nil {
+		t.Fatalf("encryptWithPassphrase: %v", err)
+	}
+
+	masterPassphrase = "wrong passphrase"
+	if _, err := decryptEnvelope(env); err == nil {
+		t.Fatal("decryptEnvelope with the wrong passphrase succeeded, want an error")
+	}
+}
+
+func TestLoadConfigsMigratesPlaintext(t *testing.T) {
+	dir := t.TempDir()
+	old, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(old) })
+
+	plaintext := map[string]*EtcdConfig{
+		"prod": {Name: "prod", Endpoint: "http://127.0.0.1:2379"},
+	}
+	content, err := jsoniter.Marshal(plaintext)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, configFile), content, 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	configs, migrated, err := loadConfigs()
+	if err != nil {
+		t.Fatalf("loadConfigs: %v", err)
+	}
+	if !migrated {
+		t.Error("loadConfigs on a legacy plaintext file: migrated = false, want true")
+	}
+	if configs["prod"] == nil || configs["prod"].Endpoint != "http://127.0.0.1:2379" {
+		t.Errorf("loadConfigs = %+v, want the prod config from the plaintext file", configs)
+	}
+}
@@ -0,0 +1,66 @@
+// +build windows
+
+package main
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modcrypt32             = syscall.NewLazyDLL("crypt32.dll")
+	modkernel32            = syscall.NewLazyDLL("kernel32.dll")
+	procCryptProtectData   = modcrypt32.NewProc("CryptProtectData")
+	procCryptUnprotectData = modcrypt32.NewProc("CryptUnprotectData")
+	procLocalFree          = modkernel32.NewProc("LocalFree")
+)
+
+type dataBlob struct {
+	cbData uint32
+	pbData *byte
+}
+
+func newBlob(d []byte) *dataBlob {
+	if len(d) == 0 {
+		return &dataBlob{}
+	}
+	return &dataBlob{cbData: uint32(len(d)), pbData: &d[0]}
+}
+
+func (b *dataBlob) bytes() []byte {
+	d := make([]byte, b.cbData)
+	copy(d, (*[1 << 30]byte)(unsafe.Pointer(b.pbData))[:b.cbData])
+	return d
+}
+
+// dpapiProtect encrypts data with the current Windows user's DPAPI key, so
+// only this account on this machine can ever decrypt config.json.
+func dpapiProtect(data []byte) ([]byte, error) {
+	var out dataBlob
+	in := newBlob(data)
+	r, _, err := procCryptProtectData.Call(
+		uintptr(unsafe.Pointer(in)),
+		0, 0, 0, 0, 0,
+		uintptr(unsafe.Pointer(&out)),
+	)
+	if r == 0 {
+		return nil, err
+	}
+	defer procLocalFree.Call(uintptr(unsafe.Pointer(out.pbData)))
+	return out.bytes(), nil
+}
+
+func dpapiUnprotect(data []byte) ([]byte, error) {
+	var out dataBlob
+	in := newBlob(data)
+	r, _, err := procCryptUnprotectData.Call(
+		uintptr(unsafe.Pointer(in)),
+		0, 0, 0, 0, 0,
+		uintptr(unsafe.Pointer(&out)),
+	)
+	if r == 0 {
+		return nil, err
+	}
+	defer procLocalFree.Call(uintptr(unsafe.Pointer(out.pbData)))
+	return out.bytes(), nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"go.etcd.io/etcd/clientv3"
+)
+
+func TestKVRecordJSONRoundTrip(t *testing.T) {
+	want := []KVRecord{
+		{Key: "/foo/bar", Value: "baz", Lease: 42, CreateRevision: 1, ModRevision: 2},
+		{Key: "/foo/empty", Value: ""},
+	}
+
+	content, err := jsoniter.MarshalIndent(want, "", "  ")
+	if err != nil {
+		t.Fatalf("MarshalIndent: %v", err)
+	}
+
+	var got []KVRecord
+	if err := jsoniter.Unmarshal(content, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("round-tripped %d records, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("record %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestChunkOps(t *testing.T) {
+	newOps := func(n int) []clientv3.Op {
+		ops := make([]clientv3.Op, n)
+		for i := range ops {
+			ops[i] = clientv3.OpPut(string(rune('a'+i)), "")
+		}
+		return ops
+	}
+
+	tests := []struct {
+		name      string
+		numOps    int
+		size      int
+		wantSizes []int
+	}{
+		{"empty", 0, 128, nil},
+		{"single batch under size", 5, 128, []int{5}},
+		{"exact multiple", 256, 128, []int{128, 128}},
+		{"remainder", 130, 128, []int{128, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batches := chunkOps(newOps(tt.numOps), tt.size)
+			if len(batches) != len(tt.wantSizes) {
+				t.Fatalf("chunkOps produced %d batches, want %d", len(batches), len(tt.wantSizes))
+			}
+			total := 0
+			for i, b := range batches {
+				if len(b) != tt.wantSizes[i] {
+					t.Errorf("batch %d has %d ops, want %d", i, len(b), tt.wantSizes[i])
+				}
+				total += len(b)
+			}
+			if total != tt.numOps {
+				t.Errorf("chunkOps covered %d ops, want %d", total, tt.numOps)
+			}
+		})
+	}
+}
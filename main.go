@@ -2,26 +2,50 @@ package main
 
 import (
 	"context"
+	"fmt"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/lxn/walk"
 	. "github.com/lxn/walk/declarative"
 	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/pkg/transport"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"golang.org/x/net/proxy"
+	"google.golang.org/grpc"
+	"io"
 	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+// Node is a key or directory entry in a single connection's keyspace tree.
+// It is always owned by exactly one Tab. Directory nodes are populated
+// lazily: loaded stays false until their first page has been fetched, and
+// hasMore/cursor track where the next page should resume from.
 type Node struct {
-	name      string
-	key       string
-	icon      string
-	parent    *Node
-	children  []*Node
-	rootName  string
-	connected bool
+	name     string
+	key      string
+	icon     string
+	parent   *Node
+	children []*Node
+	rootName string
+	loaded   bool
+	hasMore  bool
+	cursor   string
+	isMore   bool
+
+	// loadPrefix is the prefix this node's first page was scoped to (e.g.
+	// node.prefix()+"/"). "Load more" reuses it verbatim rather than
+	// recomputing node.prefix(), since GetPrefixRangeEnd does plain
+	// byte-prefix matching: dropping the trailing separator would widen
+	// the range to sibling keys whose name merely starts with this one's.
+	loadPrefix string
 }
 
 func newNode(name, key, icon, rootName string, parent *Node) *Node {
@@ -34,6 +58,12 @@ func newNode(name, key, icon, rootName string, parent *Node) *Node {
 	}
 }
 
+func newLoadMoreNode(parent *Node) *Node {
+	n := newNode("Load more...", "", "img/more.ico", parent.rootName, parent)
+	n.isMore = true
+	return n
+}
+
 var _ walk.TreeItem = new(Node)
 
 func (d *Node) Text() string {
@@ -93,27 +123,83 @@ func (d *Node) addNode(keys []string, key, rootName string) {
 	child.addNode(keys[1:], key, rootName)
 }
 
-func (d *Node) refreshNodeIcon(icon string) {
+// removeChild splices child out of d.children so it stops showing up on the
+// next PublishItemsReset(d) - PublishItemRemoved alone only tells walk to
+// drop the item from its own view, it doesn't touch the backing slice.
+func (d *Node) removeChild(child *Node) {
+	for i, c := range d.children {
+		if c == child {
+			d.children = append(d.children[:i], d.children[i+1:]...)
+			return
+		}
+	}
+}
+
+// findNode walks keys down from d the same way addNode does, without
+// creating anything, returning nil if no such descendant exists.
+func (d *Node) findNode(keys []string) *Node {
+	if len(keys) == 0 {
+		return d
+	}
+	for _, c := range d.children {
+		if c.name == keys[0] {
+			return c.findNode(keys[1:])
+		}
+	}
+	return nil
+}
+
+// isLeaf reports whether this node represents an actual etcd key rather than
+// a directory grouping of a shared key prefix. Unlike checking children==nil,
+// this stays correct for directories whose first page hasn't loaded yet.
+func (d *Node) isLeaf() bool {
+	return d.icon == "img/file.ico"
+}
+
+// prefix rebuilds this node's etcd key prefix from its position in the tree,
+// e.g. "/foo/bar". It's used to scope a paginated Get to this node's subtree.
+func (d *Node) prefix() string {
+	if d.parent == nil {
+		return "/"
+	}
+	return strings.TrimRight(d.parent.prefix(), "/") + "/" + d.name
+}
+
+// relativeKeys splits key into the path segments below base, the prefix a
+// paginated Get/Watch/search was scoped to. Passing these (rather than
+// key's full absolute path) to addNode is what lets it attach new children
+// directly under the node owning that scope instead of re-creating the
+// scope's own name as a spurious child of itself.
+func relativeKeys(base, key string) []string {
+	rel := strings.TrimPrefix(key, strings.TrimRight(base, "/"))
+	return strings.Split(strings.TrimPrefix(rel, "/"), "/")
+}
+
+// dropLoadMore removes this node's "Load more..." pseudo-child, if any, so a
+// freshly fetched page can decide whether to re-add it.
+func (d *Node) dropLoadMore() {
+	for i, c := range d.children {
+		if c.isMore {
+			d.children = append(d.children[:i], d.children[i+1:]...)
+			return
+		}
+	}
+}
+
+func (d *Node) refreshNodeIcon(treeModel *NodeTreeModel, icon string) {
 	d.icon = icon
 	treeModel.PublishItemChanged(d)
 }
 
+// NodeTreeModel exposes a Tab's keyspace as a walk.TreeModel. root itself is
+// never shown; its children are the tree's visible roots.
 type NodeTreeModel struct {
 	walk.TreeModelBase
-	roots []*Node
+	root *Node
 }
 
-func newNodeTreeModel() (*NodeTreeModel, error) {
-	model := new(NodeTreeModel)
-
-	root := newNode("All", "", "img/menu.ico", "", nil)
-	model.roots = append(model.roots, root)
-	for _, ec := range etcdConfigs {
-		root.children = append(root.children,
-			newNode(ec.Name, "", "img/unconnected.ico", "", root))
-	}
-
-	return model, nil
+func newNodeTreeModel(root *Node) *NodeTreeModel {
+	return &NodeTreeModel{root: root}
 }
 
 var _ walk.TreeModel = new(NodeTreeModel)
@@ -123,11 +209,72 @@ func (*NodeTreeModel) LazyPopulation() bool {
 }
 
 func (m *NodeTreeModel) RootCount() int {
-	return len(m.roots)
+	return len(m.root.children)
 }
 
 func (m *NodeTreeModel) RootAt(index int) walk.TreeItem {
-	return m.roots[index]
+	return m.root.children[index]
+}
+
+// ConnItem is a saved connection as shown in the sidebar. Its icon reflects
+// the connection status recorded on its EtcdConfig.
+type ConnItem struct {
+	ec *EtcdConfig
+}
+
+var _ walk.TreeItem = new(ConnItem)
+
+func (c *ConnItem) Text() string {
+	return c.ec.Name
+}
+
+func (c *ConnItem) Parent() walk.TreeItem {
+	return nil
+}
+
+func (c *ConnItem) ChildCount() int {
+	return 0
+}
+
+func (c *ConnItem) ChildAt(index int) walk.TreeItem {
+	return nil
+}
+
+func (c *ConnItem) Image() interface{} {
+	icon := "img/unconnected.ico"
+	switch c.ec.getStatus() {
+	case StatusConnected:
+		icon = "img/connected.ico"
+	case StatusDegraded:
+		icon = "img/degraded.ico"
+	case StatusDisconnected:
+		icon = "img/disconnected.ico"
+	}
+	img, err := walk.NewIconFromFile(icon)
+	if err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		return nil
+	}
+	return img
+}
+
+type ConnListModel struct {
+	walk.TreeModelBase
+	items []*ConnItem
+}
+
+var _ walk.TreeModel = new(ConnListModel)
+
+func (*ConnListModel) LazyPopulation() bool {
+	return false
+}
+
+func (m *ConnListModel) RootCount() int {
+	return len(m.items)
+}
+
+func (m *ConnListModel) RootAt(index int) walk.TreeItem {
+	return m.items[index]
 }
 
 type editRequiredValidator struct {
@@ -187,7 +334,65 @@ type EtcdConfig struct {
 	Port     float64
 	Username string
 	Password string
+	PageSize float64
+
+	// TLS/mTLS. CACertPath alone gets server-auth TLS; ClientCertPath and
+	// ClientKeyPath additionally enable client-cert (mTLS) auth.
+	CACertPath         string
+	ClientCertPath     string
+	ClientKeyPath      string
+	InsecureSkipVerify bool
+
+	// ProxyURL is an optional SOCKS5 or HTTP(S) proxy, e.g.
+	// "socks5://127.0.0.1:1080" or "http://127.0.0.1:8080".
+	ProxyURL string
+
+	// clientMu guards Client and Status: connect/disconnect on the UI
+	// goroutine and reconnectClient/setConnStatus on the supervisor
+	// goroutine both touch them, so all sides must go through
+	// getClient/setClient and getStatus/setStatus rather than touching
+	// Client or Status directly.
+	clientMu sync.Mutex
 	Client   *clientv3.Client `json:"-"`
+
+	// Status is maintained by this connection's supervisor goroutine; see
+	// superviseConnection in supervisor.go.
+	Status ConnStatus `json:"-"`
+}
+
+// getClient returns ec's current client under clientMu.
+func (ec *EtcdConfig) getClient() *clientv3.Client {
+	ec.clientMu.Lock()
+	defer ec.clientMu.Unlock()
+	return ec.Client
+}
+
+// setClient swaps in client under clientMu and returns the previous one
+// (nil if there wasn't one), leaving it to the caller to Close it.
+func (ec *EtcdConfig) setClient(client *clientv3.Client) *clientv3.Client {
+	ec.clientMu.Lock()
+	defer ec.clientMu.Unlock()
+	old := ec.Client
+	ec.Client = client
+	return old
+}
+
+// getStatus returns ec's current status under clientMu.
+func (ec *EtcdConfig) getStatus() ConnStatus {
+	ec.clientMu.Lock()
+	defer ec.clientMu.Unlock()
+	return ec.Status
+}
+
+// setStatus sets ec's status under clientMu and reports whether it actually
+// changed, so callers such as setConnStatus only emit an event on a real
+// transition.
+func (ec *EtcdConfig) setStatus(status ConnStatus) (changed bool) {
+	ec.clientMu.Lock()
+	defer ec.clientMu.Unlock()
+	changed = ec.Status != status
+	ec.Status = status
+	return changed
 }
 
 var etcdConfigs map[string]*EtcdConfig
@@ -293,25 +498,28 @@ func createSearchDialog() *walk.Dialog {
 	return dlg
 }
 
-func createAddNewConnectionDialog() *walk.Dialog {
+type KeyNameInput struct {
+	Key string
+}
+
+var keyNameInput string
+
+func createKeyNameDialog(title, initial string) *walk.Dialog {
 	var db *walk.DataBinder
 	var dlg *walk.Dialog
-	var savePB, cancelPB *walk.PushButton
+	var okPB, cancelPB *walk.PushButton
 
-	ec := new(EtcdConfig)
-	ec.Name = "ConnectionName"
-	ec.Host = "127.0.0.1"
-	ec.Port = 2379
+	input := &KeyNameInput{Key: initial}
 
 	if err := (Dialog{
 		AssignTo:      &dlg,
-		Title:         "Add New Connection",
-		DefaultButton: &savePB,
+		Title:         title,
+		DefaultButton: &okPB,
 		CancelButton:  &cancelPB,
-		MinSize:       Size{350, 200},
+		MinSize:       Size{350, 150},
 		DataBinder: DataBinder{
 			AssignTo:       &db,
-			DataSource:     ec,
+			DataSource:     input,
 			ErrorPresenter: ToolTipErrorPresenter{},
 		},
 		Layout: VBox{},
@@ -319,63 +527,95 @@ func createAddNewConnectionDialog() *walk.Dialog {
 			Composite{
 				Layout: Grid{Columns: 2},
 				Children: []Widget{
-					Label{Text: "Name:"},
-					LineEdit{Text: Bind("Name", EditRequired{})},
-					Label{Text: "Address:"},
-					Composite{
-						Layout: HBox{MarginsZero: true},
-						Children: []Widget{
-							LineEdit{Text: Bind("Host", EditRequired{})},
-							Label{Text: ":"},
-							NumberEdit{
-								Value:              Bind("Port", NumberRequired{}),
-								SpinButtonsVisible: true,
-								MinSize:            Size{Width: 80},
-							},
-						},
-					},
-					Label{Text: "Username:"},
-					LineEdit{Text: Bind("Username")},
-					Label{Text: "Password:"},
-					LineEdit{PasswordMode: true, Text: Bind("Password")},
+					Label{Text: "Key:"},
+					LineEdit{Text: Bind("Key", EditRequired{})},
 				},
 			},
 			Composite{
-				Layout: HBox{},
+				Layout: HBox{MarginsZero: true},
 				Children: []Widget{
 					HSpacer{},
 					PushButton{
-						AssignTo: &savePB,
-						Text:     "Save",
+						AssignTo: &okPB,
+						Text:     "OK",
 						OnClicked: func() {
 							if err := db.Submit(); err != nil {
 								zap.L().Error("Error", zap.Error(err))
-								walk.MsgBox(mainWindow, "Error", "Save failed.", walk.MsgBoxIconError)
+								walk.MsgBox(mainWindow, "Error", "Please enter a key.", walk.MsgBoxIconError)
 								return
 							}
+							keyNameInput = input.Key
+							dlg.Accept()
+						},
+					},
+					PushButton{
+						AssignTo:  &cancelPB,
+						Text:      "Cancel",
+						OnClicked: func() { dlg.Cancel() },
+					},
+					HSpacer{},
+				},
+			},
+		},
+	}.Create(mainWindow)); err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		panic(err)
+	}
 
-							if etcdConfigs[ec.Name] != nil {
-								walk.MsgBox(mainWindow, "Warning", "Please enter a name that does not exist.", walk.MsgBoxIconWarning)
-								return
-							}
+	return dlg
+}
 
-							ec.Endpoint = "http://" + ec.Host + ":" + strconv.FormatFloat(ec.Port, 'f', -1, 64)
-							etcdConfigs[ec.Name] = ec
+type LeaseRequest struct {
+	TTL float64
+}
 
-							content, err := jsoniter.Marshal(etcdConfigs)
-							if err != nil {
-								zap.L().Error("Error", zap.Error(err))
-								walk.MsgBox(mainWindow, "Error", "Save failed.", walk.MsgBoxIconError)
-								return
-							}
-							if err := ioutil.WriteFile("config.json", content, 0777); err != nil {
+var grantedTTL int64
+
+func createGrantLeaseDialog() *walk.Dialog {
+	var db *walk.DataBinder
+	var dlg *walk.Dialog
+	var grantPB, cancelPB *walk.PushButton
+
+	req := &LeaseRequest{TTL: 60}
+
+	if err := (Dialog{
+		AssignTo:      &dlg,
+		Title:         "Grant Lease",
+		DefaultButton: &grantPB,
+		CancelButton:  &cancelPB,
+		MinSize:       Size{350, 150},
+		DataBinder: DataBinder{
+			AssignTo:       &db,
+			DataSource:     req,
+			ErrorPresenter: ToolTipErrorPresenter{},
+		},
+		Layout: VBox{},
+		Children: []Widget{
+			Composite{
+				Layout: Grid{Columns: 2},
+				Children: []Widget{
+					Label{Text: "TTL (seconds):"},
+					NumberEdit{
+						Value:              Bind("TTL", NumberRequired{}),
+						SpinButtonsVisible: true,
+						MinSize:            Size{Width: 80},
+					},
+				},
+			},
+			Composite{
+				Layout: HBox{MarginsZero: true},
+				Children: []Widget{
+					HSpacer{},
+					PushButton{
+						AssignTo: &grantPB,
+						Text:     "Grant",
+						OnClicked: func() {
+							if err := db.Submit(); err != nil {
 								zap.L().Error("Error", zap.Error(err))
-								walk.MsgBox(mainWindow, "Error", "Save failed.", walk.MsgBoxIconError)
+								walk.MsgBox(mainWindow, "Error", "Please enter a TTL.", walk.MsgBoxIconError)
 								return
 							}
-
-							treeModel.PublishItemInserted(newNode(ec.Name, "", "img/unconnected.ico", "", treeModel.roots[0]))
-
+							grantedTTL = int64(req.TTL)
 							dlg.Accept()
 						},
 					},
@@ -396,365 +636,1525 @@ func createAddNewConnectionDialog() *walk.Dialog {
 	return dlg
 }
 
-var mousePosition *MousePosition
-
-type MousePosition struct {
-	x int
-	y int
+// KVRecord is the JSON shape used by keyspace export/import.
+type KVRecord struct {
+	Key            string `json:"key"`
+	Value          string `json:"value"`
+	Lease          int64  `json:"lease"`
+	CreateRevision int64  `json:"create_revision"`
+	ModRevision    int64  `json:"mod_revision"`
 }
 
-func (mp *MousePosition) resetMousePosition(x, y int) {
-	mp.x = x
-	mp.y = y
+type ImportOptions struct {
+	Mode string
 }
 
-var mainWindow *walk.MainWindow
-var nodes *walk.TreeView
-var key *walk.TextEdit
-var value *walk.TextEdit
-var splitter *walk.Splitter
-var showConnectAction *walk.Action
-var showReconnectAction *walk.Action
-var showDisconnectAction *walk.Action
-var showSearchAction *walk.Action
-var showDeleteAction *walk.Action
-
-var treeModel *NodeTreeModel
-
-func main() {
-	cfg := zap.NewProductionConfig()
-	cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-	cfg.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
-	cfg.OutputPaths = []string{"ETCDBox.log"}
-	logger, err := cfg.Build()
-	if err != nil {
-		panic(err)
-	}
-	zap.ReplaceGlobals(logger)
-
-	mousePosition = &MousePosition{0, 0}
+var importMode string
 
-	etcdConfigs = make(map[string]*EtcdConfig)
-	f, err := ioutil.ReadFile("config.json")
-	if err != nil {
-		zap.L().Error("Error", zap.Error(err))
-		panic(err)
-	}
-	if err := jsoniter.UnmarshalFromString(string(f), &etcdConfigs); err != nil {
-		zap.L().Error("Error", zap.Error(err))
-		panic(err)
-	}
+func createImportOptionsDialog() *walk.Dialog {
+	var db *walk.DataBinder
+	var dlg *walk.Dialog
+	var importPB, cancelPB *walk.PushButton
 
-	treeModel, err = newNodeTreeModel()
-	if err != nil {
-		zap.L().Error("Error", zap.Error(err))
-		panic(err)
-	}
+	opts := &ImportOptions{Mode: "skip"}
 
-	if err := (MainWindow{
-		AssignTo: &mainWindow,
-		Title:    "ETCD Box",
-		MinSize:  Size{600, 400},
-		Layout:   VBox{},
+	if err := (Dialog{
+		AssignTo:      &dlg,
+		Title:         "Import Options",
+		DefaultButton: &importPB,
+		CancelButton:  &cancelPB,
+		MinSize:       Size{350, 150},
+		DataBinder: DataBinder{
+			AssignTo:   &db,
+			DataSource: opts,
+		},
+		Layout: VBox{},
 		Children: []Widget{
+			Composite{
+				Layout: Grid{Columns: 2},
+				Children: []Widget{
+					Label{Text: "Existing keys:"},
+					ComboBox{
+						Value: Bind("Mode"),
+						Model: []string{"skip", "overwrite", "dry-run"},
+					},
+				},
+			},
 			Composite{
 				Layout: HBox{MarginsZero: true},
 				Children: []Widget{
+					HSpacer{},
 					PushButton{
-						Text: " Add New Connection ",
+						AssignTo: &importPB,
+						Text:     "Import",
 						OnClicked: func() {
-							createAddNewConnectionDialog().Run()
+							if err := db.Submit(); err != nil {
+								zap.L().Error("Error", zap.Error(err))
+								return
+							}
+							importMode = opts.Mode
+							dlg.Accept()
 						},
 					},
+					PushButton{
+						AssignTo:  &cancelPB,
+						Text:      "Cancel",
+						OnClicked: func() { dlg.Cancel() },
+					},
 					HSpacer{},
 				},
 			},
-			HSplitter{
-				AssignTo: &splitter,
-				Children: []Widget{
-					TreeView{
-						AssignTo:      &nodes,
-						Model:         treeModel,
-						ItemHeight:    20,
-						StretchFactor: 1,
-						ContextMenuItems: []MenuItem{
-							Action{
-								AssignTo: &showConnectAction,
-								Text:     "Connect",
-								OnTriggered: func() {
-									if mousePosition != nil {
-										if item := nodes.ItemAt(mousePosition.x, mousePosition.y); item != nil {
-											node := item.(*Node)
-											connect(node)
-										}
-									}
-								},
-							},
-							Action{
-								AssignTo: &showReconnectAction,
-								Text:     "Reconnect",
-								OnTriggered: func() {
-									if mousePosition != nil {
-										if item := nodes.ItemAt(mousePosition.x, mousePosition.y); item != nil {
-											node := item.(*Node)
-											disconnect(node)
-										}
-										if item := nodes.ItemAt(mousePosition.x, mousePosition.y); item != nil {
-											node := item.(*Node)
-											connect(node)
-										}
-									}
-								},
-							},
-							Action{
-								AssignTo: &showDisconnectAction,
-								Text:     "Disconnect",
-								OnTriggered: func() {
-									if mousePosition != nil {
-										if item := nodes.ItemAt(mousePosition.x, mousePosition.y); item != nil {
-											node := item.(*Node)
-											disconnect(node)
-										}
-									}
-								},
+		},
+	}.Create(mainWindow)); err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		panic(err)
+	}
+
+	return dlg
+}
+
+// exportKeyspace writes every key under prefix to path as a JSON array of
+// KVRecord, the same shape importKeyspace reads back.
+func exportKeyspace(ec *EtcdConfig, prefix, path string) (int, error) {
+	resp, err := ec.getClient().Get(context.Background(), prefix, clientv3.WithPrefix())
+	if err != nil {
+		return 0, err
+	}
+	records := make([]KVRecord, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		records = append(records, KVRecord{
+			Key:            string(kv.Key),
+			Value:          string(kv.Value),
+			Lease:          int64(kv.Lease),
+			CreateRevision: kv.CreateRevision,
+			ModRevision:    kv.ModRevision,
+		})
+	}
+	content, err := jsoniter.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if err := ioutil.WriteFile(path, content, 0666); err != nil {
+		return 0, err
+	}
+	return len(records), nil
+}
+
+// importTxnBatchSize caps how many Put ops go into a single Txn. etcd
+// rejects transactions larger than its --max-txn-ops (128 by default), so
+// large imports must be chunked rather than committed in one Txn.
+const importTxnBatchSize = 128
+
+// importKeyspace replays a JSON export of KVRecords via batched Txn calls.
+// In "dry-run" mode nothing is written; the counts still reflect what would
+// have happened.
+func importKeyspace(ec *EtcdConfig, path, mode string) (imported, skipped int, err error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	var records []KVRecord
+	if err := jsoniter.Unmarshal(content, &records); err != nil {
+		return 0, 0, err
+	}
+
+	client := ec.getClient()
+	var ops []clientv3.Op
+	for _, r := range records {
+		if mode != "overwrite" {
+			existing, err := client.Get(context.Background(), r.Key)
+			if err != nil {
+				return imported, skipped, err
+			}
+			if len(existing.Kvs) > 0 {
+				skipped++
+				continue
+			}
+		}
+		imported++
+		if mode == "dry-run" {
+			continue
+		}
+		ops = append(ops, clientv3.OpPut(r.Key, r.Value))
+	}
+	for _, batch := range chunkOps(ops, importTxnBatchSize) {
+		if _, err := client.Txn(context.Background()).Then(batch...).Commit(); err != nil {
+			return imported, skipped, err
+		}
+	}
+	return imported, skipped, nil
+}
+
+// chunkOps splits ops into batches of at most size ops each, preserving
+// order, so a Txn never exceeds etcd's --max-txn-ops limit.
+func chunkOps(ops []clientv3.Op, size int) [][]clientv3.Op {
+	var batches [][]clientv3.Op
+	for len(ops) > 0 {
+		n := size
+		if n > len(ops) {
+			n = len(ops)
+		}
+		batches = append(batches, ops[:n])
+		ops = ops[n:]
+	}
+	return batches
+}
+
+// snapshotSave streams a full database snapshot to a local .db file via the
+// maintenance API, the same mechanism etcdctl snapshot save uses.
+func snapshotSave(ec *EtcdConfig, path string) error {
+	rc, err := clientv3.NewMaintenance(ec.getClient()).Snapshot(context.Background())
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, rc)
+	return err
+}
+
+// browseForFile lets the user pick a file for a certificate/key LineEdit
+// and writes the chosen path back into it.
+func browseForFile(owner walk.Form, edit *walk.LineEdit, title, filter string) {
+	dlg := new(walk.FileDialog)
+	dlg.Title = title
+	dlg.Filter = filter
+	if ok, err := dlg.ShowOpen(owner); err != nil {
+		zap.L().Error("Error", zap.Error(err))
+	} else if ok {
+		edit.SetText(dlg.FilePath)
+	}
+}
+
+func createAddNewConnectionDialog() *walk.Dialog {
+	var db *walk.DataBinder
+	var dlg *walk.Dialog
+	var savePB, cancelPB *walk.PushButton
+	var caEdit, certEdit, keyEdit *walk.LineEdit
+
+	ec := new(EtcdConfig)
+	ec.Name = "ConnectionName"
+	ec.Host = "127.0.0.1"
+	ec.Port = 2379
+	ec.PageSize = 500
+
+	if err := (Dialog{
+		AssignTo:      &dlg,
+		Title:         "Add New Connection",
+		DefaultButton: &savePB,
+		CancelButton:  &cancelPB,
+		MinSize:       Size{400, 320},
+		DataBinder: DataBinder{
+			AssignTo:       &db,
+			DataSource:     ec,
+			ErrorPresenter: ToolTipErrorPresenter{},
+		},
+		Layout: VBox{},
+		Children: []Widget{
+			Composite{
+				Layout: Grid{Columns: 2},
+				Children: []Widget{
+					Label{Text: "Name:"},
+					LineEdit{Text: Bind("Name", EditRequired{})},
+					Label{Text: "Address:"},
+					Composite{
+						Layout: HBox{MarginsZero: true},
+						Children: []Widget{
+							LineEdit{Text: Bind("Host", EditRequired{})},
+							Label{Text: ":"},
+							NumberEdit{
+								Value:              Bind("Port", NumberRequired{}),
+								SpinButtonsVisible: true,
+								MinSize:            Size{Width: 80},
 							},
-							Action{
-								AssignTo: &showSearchAction,
-								Text:     "Search",
-								OnTriggered: func() {
-									if mousePosition != nil {
-										if item := nodes.ItemAt(mousePosition.x, mousePosition.y); item != nil {
-											node := item.(*Node)
-											if createSearchDialog().Run() == walk.DlgCmdOK {
-												search(searchKey, node)
-											}
-										}
-									}
+						},
+					},
+					Label{Text: "Username:"},
+					LineEdit{Text: Bind("Username")},
+					Label{Text: "Password:"},
+					LineEdit{PasswordMode: true, Text: Bind("Password")},
+					Label{Text: "Page Size:"},
+					NumberEdit{
+						Value:              Bind("PageSize", NumberRequired{}),
+						SpinButtonsVisible: true,
+						MinSize:            Size{Width: 80},
+					},
+					Label{Text: "CA Cert:"},
+					Composite{
+						Layout: HBox{MarginsZero: true},
+						Children: []Widget{
+							LineEdit{AssignTo: &caEdit, Text: Bind("CACertPath")},
+							PushButton{
+								Text:      "...",
+								MaxSize:   Size{Width: 30},
+								OnClicked: func() {
+									browseForFile(dlg, caEdit, "Select CA Certificate", "Certificates (*.crt;*.pem)|*.crt;*.pem|All files (*.*)|*.*")
 								},
 							},
-							Action{
-								AssignTo: &showDeleteAction,
-								Text:     "Delete",
-								OnTriggered: func() {
-									if mousePosition != nil {
-										if item := nodes.ItemAt(mousePosition.x, mousePosition.y); item != nil {
-											node := item.(*Node)
-											delete(etcdConfigs, node.name)
-											content, err := jsoniter.Marshal(etcdConfigs)
-											if err != nil {
-												zap.L().Error("Error", zap.Error(err))
-												walk.MsgBox(mainWindow, "Error", "Delete failed.", walk.MsgBoxIconError)
-												return
-											}
-											if err := ioutil.WriteFile("config.json", content, 0777); err != nil {
-												zap.L().Error("Error", zap.Error(err))
-												walk.MsgBox(mainWindow, "Error", "Delete failed.", walk.MsgBoxIconError)
-												return
-											}
-
-											disconnect(node)
-											treeModel.PublishItemRemoved(node)
-										}
-									}
+						},
+					},
+					Label{Text: "Client Cert:"},
+					Composite{
+						Layout: HBox{MarginsZero: true},
+						Children: []Widget{
+							LineEdit{AssignTo: &certEdit, Text: Bind("ClientCertPath")},
+							PushButton{
+								Text:      "...",
+								MaxSize:   Size{Width: 30},
+								OnClicked: func() {
+									browseForFile(dlg, certEdit, "Select Client Certificate", "Certificates (*.crt;*.pem)|*.crt;*.pem|All files (*.*)|*.*")
 								},
 							},
 						},
-						OnMouseDown: func(x, y int, button walk.MouseButton) {
-							if button == walk.LeftButton {
-								if item := nodes.ItemAt(x, y); item != nil {
-									node := item.(*Node)
-									if node.parent != nil && node.key == "" && !node.connected {
-										connect(node)
-									} else if node.children == nil && node.key != "" {
-										if ec := etcdConfigs[node.rootName]; ec != nil && ec.Client != nil {
-											client := ec.Client
-											resp, err := client.Get(context.Background(), node.key)
-											if err != nil {
-												zap.L().Error("Error", zap.Error(err))
-												walk.MsgBox(mainWindow, "Error", "Connect failed.", walk.MsgBoxIconError)
-												return
-											}
-											if resp != nil && resp.Kvs != nil {
-												for _, v := range resp.Kvs {
-													key.SetText(node.key)
-													value.SetText(string(v.Value))
-												}
-											}
-										} else {
-											// TODO
-										}
-									} else {
-										key.SetText("")
-										value.SetText("")
-									}
-								}
-							} else if button == walk.RightButton {
-								if item := nodes.ItemAt(x, y); item != nil {
-									node := item.(*Node)
-									mousePosition.resetMousePosition(x, y)
-									if node.parent != nil && node.key == "" && !node.connected {
-										showConnectAction.SetVisible(true)
-										showReconnectAction.SetVisible(false)
-										showDisconnectAction.SetVisible(false)
-										showSearchAction.SetVisible(false)
-										showDeleteAction.SetVisible(true)
-									} else if node.parent != nil && node.key == "" && node.connected {
-										showConnectAction.SetVisible(false)
-										showReconnectAction.SetVisible(true)
-										showDisconnectAction.SetVisible(true)
-										showSearchAction.SetVisible(true)
-										showDeleteAction.SetVisible(true)
-									} else {
-										showConnectAction.SetVisible(false)
-										showReconnectAction.SetVisible(false)
-										showDisconnectAction.SetVisible(false)
-										showSearchAction.SetVisible(false)
-										showDeleteAction.SetVisible(false)
-									}
-								}
-							}
-						},
 					},
+					Label{Text: "Client Key:"},
 					Composite{
-						Layout:        VBox{MarginsZero: true},
-						StretchFactor: 3,
+						Layout: HBox{MarginsZero: true},
 						Children: []Widget{
-							TextLabel{
-								Text: "Key:",
-							},
-							TextEdit{
-								Text:          "",
-								CompactHeight: true,
-								ReadOnly:      true,
-								AssignTo:      &key,
-							},
-							TextLabel{
-								Text: "Value:",
-							},
-							TextEdit{
-								AssignTo: &value,
+							LineEdit{AssignTo: &keyEdit, Text: Bind("ClientKeyPath")},
+							PushButton{
+								Text:      "...",
+								MaxSize:   Size{Width: 30},
+								OnClicked: func() {
+									browseForFile(dlg, keyEdit, "Select Client Key", "Keys (*.key;*.pem)|*.key;*.pem|All files (*.*)|*.*")
+								},
 							},
 						},
 					},
+					Label{Text: ""},
+					CheckBox{Text: "Skip TLS certificate verification", Checked: Bind("InsecureSkipVerify")},
+					Label{Text: "Proxy URL:"},
+					LineEdit{Text: Bind("ProxyURL")},
 				},
-				RowSpan: 10,
 			},
-			Label{
-				Text:          "Version:1.0",
-				TextAlignment: AlignFar,
+			Composite{
+				Layout: HBox{},
+				Children: []Widget{
+					HSpacer{},
+					PushButton{
+						AssignTo: &savePB,
+						Text:     "Save",
+						OnClicked: func() {
+							if err := db.Submit(); err != nil {
+								zap.L().Error("Error", zap.Error(err))
+								walk.MsgBox(mainWindow, "Error", "Save failed.", walk.MsgBoxIconError)
+								return
+							}
+
+							if etcdConfigs[ec.Name] != nil {
+								walk.MsgBox(mainWindow, "Warning", "Please enter a name that does not exist.", walk.MsgBoxIconWarning)
+								return
+							}
+
+							ec.Endpoint = "http://" + ec.Host + ":" + strconv.FormatFloat(ec.Port, 'f', -1, 64)
+							etcdConfigs[ec.Name] = ec
+
+							if err := saveConfigs(etcdConfigs); err != nil {
+								zap.L().Error("Error", zap.Error(err))
+								walk.MsgBox(mainWindow, "Error", "Save failed.", walk.MsgBoxIconError)
+								return
+							}
+
+							addConnItem(ec)
+
+							dlg.Accept()
+						},
+					},
+					PushButton{
+						AssignTo:  &cancelPB,
+						Text:      "Cancel",
+						OnClicked: func() { dlg.Cancel() },
+					},
+					HSpacer{},
+				},
 			},
 		},
-	}.Create()); err != nil {
+	}.Create(mainWindow)); err != nil {
 		zap.L().Error("Error", zap.Error(err))
 		panic(err)
 	}
 
-	mainWindow.Run()
+	return dlg
 }
 
-func connect(node *Node) {
-	if ec := etcdConfigs[node.name]; ec != nil {
-		createWaitDialog()
-		waitDlg.Show()
-		go func() {
-			defer func() {
-				time.Sleep(200 * time.Millisecond)
-				waitDlg.Accept()
-			}()
-			client, err := clientv3.New(clientv3.Config{
-				Endpoints:   []string{ec.Endpoint},
-				Username:    ec.Username,
-				Password:    ec.Password,
-				DialTimeout: 2 * time.Second,
-			})
-			if err != nil {
-				zap.L().Error("Error", zap.Error(err))
-				walk.MsgBox(mainWindow, "Error", "Connect failed.", walk.MsgBoxIconError)
-				return
-			}
-			timeoutCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-			defer cancel()
-			_, err = client.Status(timeoutCtx, ec.Endpoint)
-			if err != nil {
-				zap.L().Error("Error", zap.Error(err))
-				walk.MsgBox(mainWindow, "Error", "Connect failed.", walk.MsgBoxIconError)
-				return
-			}
-			ec.Client = client
-			resp, err := client.Get(context.Background(), "/",
-				clientv3.WithPrefix(), clientv3.WithKeysOnly())
-			if err != nil {
-				zap.L().Error("Error", zap.Error(err))
-				walk.MsgBox(mainWindow, "Error", "Connect failed.", walk.MsgBoxIconError)
-				return
-			}
-			if resp != nil && resp.Kvs != nil {
-				for _, v := range resp.Kvs {
-					keys := strings.Split(strings.TrimPrefix(string(v.Key), "/"), "/")
-					node.addNode(keys, string(v.Key), node.name)
-				}
-			}
-			node.refreshNodeIcon("img/connected.ico")
-			treeModel.PublishItemsReset(node)
-			if node.children != nil {
-				if err := nodes.SetExpanded(node, true); err != nil {
-					zap.L().Error("Error", zap.Error(err))
-					walk.MsgBox(mainWindow, "Error", "Connect failed.", walk.MsgBoxIconError)
-					return
-				}
-			}
-			key.SetText("")
-			value.SetText("")
-			node.connected = true
-		}()
-	} else {
-		// TODO Error
+type MousePosition struct {
+	x int
+	y int
+}
+
+func (mp *MousePosition) resetMousePosition(x, y int) {
+	mp.x = x
+	mp.y = y
+}
+
+// Tab is everything that used to be global state, scoped to one connected
+// EtcdConfig so clusters can be browsed side-by-side.
+type Tab struct {
+	ec            *EtcdConfig
+	page          *walk.TabPage
+	root          *Node
+	treeModel     *NodeTreeModel
+	treeView      *walk.TreeView
+	keyEdit       *walk.TextEdit
+	valueEdit     *walk.TextEdit
+	dirtyLabel    *walk.Label
+	leaseLabel    *walk.Label
+	ttlLabel      *walk.Label
+	watchLog      *walk.TextEdit
+	mousePosition *MousePosition
+	currentNode   *Node
+	valueDirty    bool
+	watchCancels  map[*Node]context.CancelFunc
+	superviseStop chan struct{}
+
+	// treeMu is held while paginated loads and watch events update
+	// node.children; both apply those updates on the UI goroutine via
+	// mainWindow.Synchronize.
+	treeMu sync.Mutex
+
+	showSearchAction     *walk.Action
+	showNewKeyAction     *walk.Action
+	showRenameKeyAction  *walk.Action
+	showDeleteKeyAction  *walk.Action
+	showSaveValueAction  *walk.Action
+	showGrantLeaseAction *walk.Action
+	showWatchAction      *walk.Action
+	showStopWatchAction  *walk.Action
+	showExportNodeAction *walk.Action
+}
+
+func (t *Tab) pageSize() int64 {
+	if t.ec.PageSize <= 0 {
+		return 500
 	}
+	return int64(t.ec.PageSize)
 }
 
-func search(searchKey string, node *Node) {
-	if ec := etcdConfigs[node.name]; ec != nil && ec.Client != nil {
-		node.children = nil
-		resp, err := ec.Client.Get(context.Background(), searchKey,
-			clientv3.WithPrefix(), clientv3.WithKeysOnly())
+// loadPage fetches one page of keys scoped to prefix into node, resuming
+// from node.cursor when this isn't the first page, and leaves a
+// "Load more..." pseudo-child behind when the page was full. The client.Get
+// runs on its own goroutine and the result is applied back via
+// mainWindow.Synchronize so a slow or remote cluster never blocks the UI
+// goroutine, same as startWatch's event delivery.
+func (t *Tab) loadPage(node *Node, prefix string) {
+	client := t.ec.getClient()
+	if client == nil {
+		return
+	}
+	node.loadPrefix = prefix
+	from := prefix
+	if node.cursor != "" {
+		from = node.cursor
+	}
+	go func() {
+		resp, err := client.Get(context.Background(), from,
+			clientv3.WithFromKey(),
+			clientv3.WithRange(clientv3.GetPrefixRangeEnd(prefix)),
+			clientv3.WithLimit(t.pageSize()),
+			clientv3.WithKeysOnly())
 		if err != nil {
 			zap.L().Error("Error", zap.Error(err))
-			walk.MsgBox(mainWindow, "Error", "Search failed.", walk.MsgBoxIconError)
+			mainWindow.Synchronize(func() {
+				walk.MsgBox(mainWindow, "Error", "Load failed.", walk.MsgBoxIconError)
+			})
 			return
 		}
-		if resp != nil && resp.Kvs != nil {
+
+		mainWindow.Synchronize(func() {
+			t.treeMu.Lock()
+			node.dropLoadMore()
 			for _, v := range resp.Kvs {
-				keys := strings.Split(strings.TrimPrefix(string(v.Key), "/"), "/")
-				node.addNode(keys, string(v.Key), node.name)
+				keys := relativeKeys(prefix, string(v.Key))
+				node.addNode(keys, string(v.Key), t.ec.Name)
 			}
-		}
-		if searchKey == "" {
-			node.refreshNodeIcon("img/connected.ico")
-		} else {
-			node.refreshNodeIcon("img/search.ico")
-		}
-		treeModel.PublishItemsReset(node)
-		if node.children != nil {
-			if err := nodes.SetExpanded(node, true); err != nil {
-				zap.L().Error("Error", zap.Error(err))
-				walk.MsgBox(mainWindow, "Error", "Search failed.", walk.MsgBoxIconError)
-				return
+			node.loaded = true
+			node.hasMore = resp.More
+			if node.hasMore && len(resp.Kvs) > 0 {
+				node.cursor = string(resp.Kvs[len(resp.Kvs)-1].Key) + "\x00"
+				node.children = append(node.children, newLoadMoreNode(node))
 			}
-		}
-		key.SetText("")
-		value.SetText("")
-	} else {
-		// TODO
+			t.treeMu.Unlock()
+
+			t.treeModel.PublishItemsReset(node)
+		})
+	}()
+}
+
+func (t *Tab) setValueDirty(dirty bool) {
+	t.valueDirty = dirty
+	if t.dirtyLabel != nil {
+		t.dirtyLabel.SetVisible(dirty)
 	}
 }
 
-func disconnect(node *Node) {
-	node.children = nil
-	if ec := etcdConfigs[node.rootName]; ec != nil && ec.Client != nil {
-		ec.Client.Close()
-		ec.Client = nil
+var mainWindow *walk.MainWindow
+var splitter *walk.Splitter
+var nodes *walk.TreeView
+var connListModel *ConnListModel
+var tabWidget *walk.TabWidget
+var mousePosition *MousePosition
+var statusLabel *walk.Label
+
+// tabs holds one entry per connection currently being browsed.
+var tabs = map[string]*Tab{}
+
+func main() {
+	cfg := zap.NewProductionConfig()
+	cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	cfg.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
+	cfg.OutputPaths = []string{"ETCDBox.log"}
+	logger, err := cfg.Build()
+	if err != nil {
+		panic(err)
+	}
+	zap.ReplaceGlobals(logger)
+
+	mousePosition = &MousePosition{0, 0}
+
+	configs, migrated, err := loadConfigs()
+	if err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		panic(err)
+	}
+	etcdConfigs = configs
+	if migrated {
+		zap.L().Info("Migrating plaintext config.json to encrypted storage")
+		if err := saveConfigs(etcdConfigs); err != nil {
+			zap.L().Error("Error", zap.Error(err))
+		}
+	}
+
+	connListModel = new(ConnListModel)
+	for _, ec := range etcdConfigs {
+		connListModel.items = append(connListModel.items, &ConnItem{ec: ec})
+	}
+
+	if err := (MainWindow{
+		AssignTo: &mainWindow,
+		Title:    "ETCD Box",
+		MinSize:  Size{800, 500},
+		Layout:   VBox{},
+		MenuItems: []MenuItem{
+			Menu{
+				Text: "File",
+				Items: []MenuItem{
+					Action{
+						Text:        "Export Connection...",
+						OnTriggered: exportCurrentConnection,
+					},
+					Action{
+						Text:        "Import into Connection...",
+						OnTriggered: importCurrentConnection,
+					},
+					Separator{},
+					Action{
+						Text:        "Snapshot Save...",
+						OnTriggered: snapshotSaveCurrentConnection,
+					},
+					Action{
+						Text: "Snapshot Restore...",
+						OnTriggered: func() {
+							walk.MsgBox(mainWindow, "Not supported", "Snapshot restore requires etcdutil and is not supported by this build. Use etcdctl snapshot restore instead.", walk.MsgBoxIconInformation)
+						},
+					},
+				},
+			},
+		},
+		Children: []Widget{
+			Composite{
+				Layout: HBox{MarginsZero: true},
+				Children: []Widget{
+					PushButton{
+						Text: " Add New Connection ",
+						OnClicked: func() {
+							createAddNewConnectionDialog().Run()
+						},
+					},
+					HSpacer{},
+				},
+			},
+			HSplitter{
+				AssignTo: &splitter,
+				Children: []Widget{
+					TreeView{
+						AssignTo:      &nodes,
+						Model:         connListModel,
+						ItemHeight:    20,
+						StretchFactor: 1,
+						ContextMenuItems: []MenuItem{
+							Action{
+								Text: "Connect",
+								OnTriggered: func() {
+									if item := connItemAt(mousePosition); item != nil {
+										connect(item.ec)
+									}
+								},
+							},
+							Action{
+								Text: "Reconnect",
+								OnTriggered: func() {
+									if item := connItemAt(mousePosition); item != nil {
+										disconnect(item.ec)
+										connect(item.ec)
+									}
+								},
+							},
+							Action{
+								Text: "Disconnect",
+								OnTriggered: func() {
+									if item := connItemAt(mousePosition); item != nil {
+										disconnect(item.ec)
+									}
+								},
+							},
+							Action{
+								Text: "Delete",
+								OnTriggered: func() {
+									if item := connItemAt(mousePosition); item != nil {
+										deleteConn(item)
+									}
+								},
+							},
+						},
+						OnMouseDown: func(x, y int, button walk.MouseButton) {
+							if button == walk.LeftButton {
+								if item := nodes.ItemAt(x, y); item != nil {
+									connect(item.(*ConnItem).ec)
+								}
+							} else if button == walk.RightButton {
+								mousePosition.resetMousePosition(x, y)
+							}
+						},
+					},
+					TabWidget{
+						AssignTo:      &tabWidget,
+						StretchFactor: 4,
+					},
+				},
+				RowSpan: 10,
+			},
+			Label{
+				AssignTo:      &statusLabel,
+				Text:          "Version:1.0",
+				TextAlignment: AlignFar,
+			},
+		},
+	}.Create()); err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		panic(err)
+	}
+
+	go func() {
+		for evt := range statusEvents {
+			evt := evt
+			mainWindow.Synchronize(func() {
+				handleStatusEvent(evt)
+			})
+		}
+	}()
+
+	mainWindow.Run()
+}
+
+// handleStatusEvent applies one connection health transition to the UI:
+// the sidebar icon, the watch log of that connection's tab, and the status label when
+// the event is for the tab currently in view.
+func handleStatusEvent(evt StatusEvent) {
+	ec := etcdConfigs[evt.Name]
+	if ec == nil {
+		return
+	}
+	if item := connItemFor(ec); item != nil {
+		connListModel.PublishItemChanged(item)
+	}
+	t := tabs[evt.Name]
+	if t == nil {
+		return
+	}
+	t.appendWatchLog(fmt.Sprintf("[status] %s", evt.Status))
+	if t == currentTab() {
+		statusLabel.SetText(fmt.Sprintf("%s: %s", ec.Name, evt.Status))
+	}
+}
+
+func connItemAt(mp *MousePosition) *ConnItem {
+	if mp == nil {
+		return nil
+	}
+	item := nodes.ItemAt(mp.x, mp.y)
+	if item == nil {
+		return nil
+	}
+	return item.(*ConnItem)
+}
+
+func addConnItem(ec *EtcdConfig) {
+	item := &ConnItem{ec: ec}
+	connListModel.items = append(connListModel.items, item)
+	connListModel.PublishItemInserted(item)
+}
+
+func connItemFor(ec *EtcdConfig) *ConnItem {
+	for _, item := range connListModel.items {
+		if item.ec == ec {
+			return item
+		}
+	}
+	return nil
+}
+
+// createTab builds the per-connection widgets (tree, key/value panes, watch
+// log) into a fresh TabPage. The widgets are rolled back via walk.Disposables
+// if anything fails partway through.
+func createTab(t *Tab) error {
+	var disposables walk.Disposables
+	defer disposables.Treat()
+
+	page, err := walk.NewTabPage()
+	if err != nil {
+		return err
+	}
+	disposables.Add(page)
+
+	if err := page.SetTitle(t.ec.Name); err != nil {
+		return err
+	}
+	if err := page.SetLayout(walk.NewHBoxLayout()); err != nil {
+		return err
+	}
+
+	if err := (Composite{
+		Layout: HBox{MarginsZero: true},
+		Children: []Widget{
+			TreeView{
+				AssignTo:      &t.treeView,
+				Model:         t.treeModel,
+				ItemHeight:    20,
+				StretchFactor: 1,
+				ContextMenuItems: []MenuItem{
+					Action{
+						AssignTo: &t.showSearchAction,
+						Text:     "Search",
+						OnTriggered: func() {
+							if item := t.treeView.ItemAt(t.mousePosition.x, t.mousePosition.y); item != nil {
+								if createSearchDialog().Run() == walk.DlgCmdOK {
+									t.search(searchKey, item.(*Node))
+								}
+							}
+						},
+					},
+					Action{
+						AssignTo: &t.showExportNodeAction,
+						Text:     "Export Subtree...",
+						OnTriggered: func() {
+							if item := t.treeView.ItemAt(t.mousePosition.x, t.mousePosition.y); item != nil {
+								t.exportSubtree(item.(*Node))
+							}
+						},
+					},
+					Action{
+						AssignTo: &t.showNewKeyAction,
+						Text:     "New Key",
+						OnTriggered: func() {
+							if item := t.treeView.ItemAt(t.mousePosition.x, t.mousePosition.y); item != nil {
+								t.createKey(item.(*Node))
+							}
+						},
+					},
+					Action{
+						AssignTo: &t.showRenameKeyAction,
+						Text:     "Rename",
+						OnTriggered: func() {
+							if item := t.treeView.ItemAt(t.mousePosition.x, t.mousePosition.y); item != nil {
+								t.renameKey(item.(*Node))
+							}
+						},
+					},
+					Action{
+						AssignTo: &t.showDeleteKeyAction,
+						Text:     "Delete Key",
+						OnTriggered: func() {
+							if item := t.treeView.ItemAt(t.mousePosition.x, t.mousePosition.y); item != nil {
+								t.deleteKey(item.(*Node))
+							}
+						},
+					},
+					Action{
+						AssignTo: &t.showSaveValueAction,
+						Text:     "Save Value",
+						OnTriggered: func() {
+							if item := t.treeView.ItemAt(t.mousePosition.x, t.mousePosition.y); item != nil {
+								t.saveValue(item.(*Node))
+							}
+						},
+					},
+					Action{
+						AssignTo: &t.showGrantLeaseAction,
+						Text:     "Grant Lease...",
+						OnTriggered: func() {
+							if item := t.treeView.ItemAt(t.mousePosition.x, t.mousePosition.y); item != nil {
+								t.grantLease(item.(*Node))
+							}
+						},
+					},
+					Action{
+						AssignTo: &t.showWatchAction,
+						Text:     "Watch",
+						OnTriggered: func() {
+							if item := t.treeView.ItemAt(t.mousePosition.x, t.mousePosition.y); item != nil {
+								t.startWatch(item.(*Node))
+							}
+						},
+					},
+					Action{
+						AssignTo: &t.showStopWatchAction,
+						Text:     "Stop Watch",
+						OnTriggered: func() {
+							if item := t.treeView.ItemAt(t.mousePosition.x, t.mousePosition.y); item != nil {
+								t.stopWatch(item.(*Node))
+							}
+						},
+					},
+				},
+				OnMouseDown: func(x, y int, button walk.MouseButton) {
+					if button == walk.LeftButton {
+						if item := t.treeView.ItemAt(x, y); item != nil {
+							node := item.(*Node)
+							t.onNodeClicked(node)
+						}
+					} else if button == walk.RightButton {
+						if item := t.treeView.ItemAt(x, y); item != nil {
+							t.mousePosition.resetMousePosition(x, y)
+							t.updateContextMenu(item.(*Node))
+						}
+					}
+				},
+			},
+			Composite{
+				Layout:        VBox{MarginsZero: true},
+				StretchFactor: 3,
+				Children: []Widget{
+					TextLabel{Text: "Key:"},
+					TextEdit{
+						Text:          "",
+						CompactHeight: true,
+						ReadOnly:      true,
+						AssignTo:      &t.keyEdit,
+					},
+					Composite{
+						Layout: HBox{MarginsZero: true},
+						Children: []Widget{
+							TextLabel{Text: "Lease:"},
+							Label{AssignTo: &t.leaseLabel, Text: "-"},
+							TextLabel{Text: "TTL:"},
+							Label{AssignTo: &t.ttlLabel, Text: "-"},
+							HSpacer{},
+						},
+					},
+					Composite{
+						Layout: HBox{MarginsZero: true},
+						Children: []Widget{
+							TextLabel{Text: "Value:"},
+							Label{
+								AssignTo:  &t.dirtyLabel,
+								Text:      "(unsaved changes)",
+								TextColor: walk.RGB(200, 0, 0),
+								Visible:   false,
+							},
+							HSpacer{},
+						},
+					},
+					TextEdit{
+						AssignTo: &t.valueEdit,
+						OnTextChanged: func() {
+							if t.currentNode != nil {
+								t.setValueDirty(true)
+							}
+						},
+					},
+					TextLabel{Text: "Watch Log:"},
+					TextEdit{
+						AssignTo:      &t.watchLog,
+						ReadOnly:      true,
+						CompactHeight: true,
+						MinSize:       Size{Height: 80},
+					},
+				},
+			},
+		},
+	}.Create(NewBuilder(page))); err != nil {
+		return err
+	}
+
+	if err := tabWidget.Pages().Add(page); err != nil {
+		return err
+	}
+	t.page = page
+
+	disposables.Spare()
+	return nil
+}
+
+func (t *Tab) onNodeClicked(node *Node) {
+	if node.isMore {
+		t.loadPage(node.parent, node.parent.loadPrefix)
+		return
+	}
+	if t.valueDirty && t.currentNode != nil {
+		if walk.MsgBox(mainWindow, "Unsaved changes",
+			"\""+t.currentNode.key+"\" has unsaved changes. Save them before continuing?",
+			walk.MsgBoxYesNo|walk.MsgBoxIconQuestion) == walk.DlgCmdYes {
+			t.saveValue(t.currentNode)
+		} else {
+			t.setValueDirty(false)
+		}
+	}
+	if node.isLeaf() {
+		if client := t.ec.getClient(); client != nil {
+			resp, err := client.Get(context.Background(), node.key)
+			if err != nil {
+				zap.L().Error("Error", zap.Error(err))
+				walk.MsgBox(mainWindow, "Error", "Get failed.", walk.MsgBoxIconError)
+				return
+			}
+			if resp != nil && resp.Kvs != nil {
+				for _, v := range resp.Kvs {
+					t.keyEdit.SetText(node.key)
+					t.valueEdit.SetText(string(v.Value))
+					t.showLeaseInfo(v.Lease)
+				}
+			}
+			t.currentNode = node
+			t.setValueDirty(false)
+		}
+	} else {
+		if !node.loaded {
+			t.loadPage(node, node.prefix()+"/")
+			if err := t.treeView.SetExpanded(node, true); err != nil {
+				zap.L().Error("Error", zap.Error(err))
+			}
+		}
+		t.currentNode = nil
+		t.setValueDirty(false)
+		t.keyEdit.SetText("")
+		t.valueEdit.SetText("")
+		t.leaseLabel.SetText("-")
+		t.ttlLabel.SetText("-")
+	}
+}
+
+func (t *Tab) updateContextMenu(node *Node) {
+	isLeaf := node.isLeaf()
+	t.showSearchAction.SetVisible(!isLeaf)
+	t.showExportNodeAction.SetVisible(!isLeaf)
+	t.showNewKeyAction.SetVisible(!isLeaf)
+	t.showGrantLeaseAction.SetVisible(true)
+	t.showWatchAction.SetVisible(!isLeaf && t.watchCancels[node] == nil)
+	t.showStopWatchAction.SetVisible(!isLeaf && t.watchCancels[node] != nil)
+	t.showRenameKeyAction.SetVisible(isLeaf)
+	t.showDeleteKeyAction.SetVisible(isLeaf)
+	t.showSaveValueAction.SetVisible(isLeaf)
+}
+
+func focusTab(name string) {
+	t := tabs[name]
+	if t == nil || tabWidget == nil {
+		return
+	}
+	for i := 0; i < tabWidget.Pages().Len(); i++ {
+		if tabWidget.Pages().At(i) == t.page {
+			tabWidget.SetCurrentIndex(i)
+			return
+		}
+	}
+}
+
+func currentTab() *Tab {
+	if tabWidget == nil || tabWidget.CurrentIndex() < 0 {
+		return nil
+	}
+	page := tabWidget.Pages().At(tabWidget.CurrentIndex())
+	for _, t := range tabs {
+		if t.page == page {
+			return t
+		}
+	}
+	return nil
+}
+
+func exportCurrentConnection() {
+	t := currentTab()
+	if t == nil {
+		walk.MsgBox(mainWindow, "No connection", "Open a connection tab before exporting.", walk.MsgBoxIconWarning)
+		return
+	}
+	dlg := new(walk.FileDialog)
+	dlg.Filter = "JSON files (*.json)|*.json"
+	dlg.Title = "Export Connection as JSON"
+	ok, err := dlg.ShowSave(mainWindow)
+	if err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", err.Error(), walk.MsgBoxIconError)
+		return
+	}
+	if !ok {
+		return
+	}
+	path := dlg.FilePath
+	if filepath.Ext(path) == "" {
+		path += ".json"
+	}
+	count, err := exportKeyspace(t.ec, t.root.prefix(), path)
+	if err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", err.Error(), walk.MsgBoxIconError)
+		return
+	}
+	walk.MsgBox(mainWindow, "Export complete", fmt.Sprintf("Exported %d keys to %s", count, path), walk.MsgBoxIconInformation)
+}
+
+func importCurrentConnection() {
+	t := currentTab()
+	if t == nil {
+		walk.MsgBox(mainWindow, "No connection", "Open a connection tab before importing.", walk.MsgBoxIconWarning)
+		return
+	}
+	dlg := new(walk.FileDialog)
+	dlg.Filter = "JSON files (*.json)|*.json"
+	dlg.Title = "Import JSON into Connection"
+	ok, err := dlg.ShowOpen(mainWindow)
+	if err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", err.Error(), walk.MsgBoxIconError)
+		return
+	}
+	if !ok {
+		return
+	}
+	if cmd := createImportOptionsDialog(); cmd.Run() != walk.DlgCmdOK {
+		return
+	}
+	imported, skipped, err := importKeyspace(t.ec, dlg.FilePath, importMode)
+	if err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", err.Error(), walk.MsgBoxIconError)
+		return
+	}
+	t.loadPage(t.root, t.root.prefix())
+	walk.MsgBox(mainWindow, "Import complete", fmt.Sprintf("Imported %d keys, skipped %d", imported, skipped), walk.MsgBoxIconInformation)
+}
+
+func snapshotSaveCurrentConnection() {
+	t := currentTab()
+	if t == nil {
+		walk.MsgBox(mainWindow, "No connection", "Open a connection tab before saving a snapshot.", walk.MsgBoxIconWarning)
+		return
+	}
+	dlg := new(walk.FileDialog)
+	dlg.Filter = "Snapshot files (*.db)|*.db"
+	dlg.Title = "Save etcd Snapshot"
+	ok, err := dlg.ShowSave(mainWindow)
+	if err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", err.Error(), walk.MsgBoxIconError)
+		return
+	}
+	if !ok {
+		return
+	}
+	path := dlg.FilePath
+	if filepath.Ext(path) == "" {
+		path += ".db"
+	}
+	if err := snapshotSave(t.ec, path); err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", err.Error(), walk.MsgBoxIconError)
+		return
+	}
+	walk.MsgBox(mainWindow, "Snapshot complete", fmt.Sprintf("Snapshot saved to %s", path), walk.MsgBoxIconInformation)
+}
+
+// buildClientConfig turns an EtcdConfig's TLS/mTLS/proxy settings into a
+// clientv3.Config, upgrading the endpoint to https when TLS is in use.
+func buildClientConfig(ec *EtcdConfig) (clientv3.Config, error) {
+	endpoint := ec.Endpoint
+	cfg := clientv3.Config{
+		Username:    ec.Username,
+		Password:    ec.Password,
+		DialTimeout: 2 * time.Second,
+	}
+
+	if ec.CACertPath != "" || ec.ClientCertPath != "" || ec.InsecureSkipVerify {
+		tlsInfo := transport.TLSInfo{
+			TrustedCAFile: ec.CACertPath,
+			CertFile:      ec.ClientCertPath,
+			KeyFile:       ec.ClientKeyPath,
+		}
+		tlsConfig, err := tlsInfo.ClientConfig()
+		if err != nil {
+			return clientv3.Config{}, err
+		}
+		tlsConfig.InsecureSkipVerify = ec.InsecureSkipVerify
+		cfg.TLS = tlsConfig
+		endpoint = strings.Replace(endpoint, "http://", "https://", 1)
+	}
+
+	if ec.ProxyURL != "" {
+		proxyURL, err := url.Parse(ec.ProxyURL)
+		if err != nil {
+			return clientv3.Config{}, err
+		}
+		dialer, err := proxy.FromURL(proxyURL, proxy.Direct)
+		if err != nil {
+			return clientv3.Config{}, err
+		}
+		cfg.DialOptions = append(cfg.DialOptions, grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialer.Dial("tcp", addr)
+		}))
+	}
+
+	cfg.Endpoints = []string{endpoint}
+	return cfg, nil
+}
+
+func connect(ec *EtcdConfig) {
+	if tabs[ec.Name] != nil {
+		focusTab(ec.Name)
+		return
+	}
+	createWaitDialog()
+	waitDlg.Show()
+	go func() {
+		defer func() {
+			time.Sleep(200 * time.Millisecond)
+			waitDlg.Accept()
+		}()
+		clientCfg, err := buildClientConfig(ec)
+		if err != nil {
+			zap.L().Error("Error", zap.Error(err))
+			walk.MsgBox(mainWindow, "Error", "Connect failed: invalid TLS/proxy configuration.", walk.MsgBoxIconError)
+			return
+		}
+		client, err := clientv3.New(clientCfg)
+		if err != nil {
+			zap.L().Error("Error", zap.Error(err))
+			walk.MsgBox(mainWindow, "Error", "Connect failed.", walk.MsgBoxIconError)
+			return
+		}
+		timeoutCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		_, err = client.Status(timeoutCtx, clientCfg.Endpoints[0])
+		cancel()
+		if err != nil {
+			zap.L().Error("Error", zap.Error(err))
+			walk.MsgBox(mainWindow, "Error", "Connect failed.", walk.MsgBoxIconError)
+			return
+		}
+
+		// Everything past this point builds walk widgets (createTab),
+		// publishes to the tree model (t.loadPage) and writes the shared
+		// tabs/connListModel state that other connections' goroutines
+		// (superviseConnection, another connect) also touch, so it all has
+		// to run on the UI goroutine, same as startWatch's event delivery.
+		mainWindow.Synchronize(func() {
+			ec.setClient(client)
+			ec.setStatus(StatusConnected)
+
+			root := newNode("", "", "", ec.Name, nil)
+			t := &Tab{
+				ec:            ec,
+				root:          root,
+				treeModel:     newNodeTreeModel(root),
+				mousePosition: &MousePosition{},
+				watchCancels:  make(map[*Node]context.CancelFunc),
+				superviseStop: make(chan struct{}),
+			}
+			if err := createTab(t); err != nil {
+				zap.L().Error("Error", zap.Error(err))
+				walk.MsgBox(mainWindow, "Error", "Connect failed.", walk.MsgBoxIconError)
+				ec.setClient(nil).Close()
+				ec.setStatus("")
+				return
+			}
+
+			t.loadPage(root, "/")
+
+			tabs[ec.Name] = t
+			go superviseConnection(ec, t.superviseStop)
+			focusTab(ec.Name)
+			if item := connItemFor(ec); item != nil {
+				connListModel.PublishItemChanged(item)
+			}
+		})
+	}()
+}
+
+func disconnect(ec *EtcdConfig) {
+	t := tabs[ec.Name]
+	if t == nil {
+		return
+	}
+	close(t.superviseStop)
+	ec.setStatus("")
+	for _, cancel := range t.watchCancels {
+		cancel()
+	}
+	if old := ec.setClient(nil); old != nil {
+		old.Close()
+	}
+	if tabWidget != nil {
+		tabWidget.Pages().Remove(t.page)
+	}
+	delete(tabs, ec.Name)
+	if item := connItemFor(ec); item != nil {
+		connListModel.PublishItemChanged(item)
+	}
+}
+
+func deleteConn(item *ConnItem) {
+	ec := item.ec
+	delete(etcdConfigs, ec.Name)
+	if err := saveConfigs(etcdConfigs); err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", "Delete failed.", walk.MsgBoxIconError)
+		return
+	}
+
+	disconnect(ec)
+	for i, it := range connListModel.items {
+		if it == item {
+			connListModel.items = append(connListModel.items[:i], connListModel.items[i+1:]...)
+			break
+		}
+	}
+	connListModel.PublishItemRemoved(item)
+}
+
+func (t *Tab) search(searchKey string, node *Node) {
+	client := t.ec.getClient()
+	if client == nil {
+		return
+	}
+	node.children = nil
+	node.loaded = true
+	node.hasMore = false
+	node.cursor = ""
+	resp, err := client.Get(context.Background(), searchKey,
+		clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", "Search failed.", walk.MsgBoxIconError)
+		return
+	}
+	if resp != nil && resp.Kvs != nil {
+		for _, v := range resp.Kvs {
+			keys := relativeKeys(searchKey, string(v.Key))
+			node.addNode(keys, string(v.Key), t.ec.Name)
+		}
+	}
+	if searchKey == "" {
+		node.refreshNodeIcon(t.treeModel, "img/connected.ico")
+	} else {
+		node.refreshNodeIcon(t.treeModel, "img/search.ico")
+	}
+	t.treeModel.PublishItemsReset(node)
+	if node.children != nil {
+		if err := t.treeView.SetExpanded(node, true); err != nil {
+			zap.L().Error("Error", zap.Error(err))
+			walk.MsgBox(mainWindow, "Error", "Search failed.", walk.MsgBoxIconError)
+			return
+		}
+	}
+	t.keyEdit.SetText("")
+	t.valueEdit.SetText("")
+}
+
+func (t *Tab) showLeaseInfo(leaseID int64) {
+	if leaseID == 0 {
+		t.leaseLabel.SetText("-")
+		t.ttlLabel.SetText("-")
+		return
+	}
+	t.leaseLabel.SetText(strconv.FormatInt(leaseID, 16))
+	resp, err := t.ec.getClient().TimeToLive(context.Background(), clientv3.LeaseID(leaseID))
+	if err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		t.ttlLabel.SetText("-")
+		return
+	}
+	t.ttlLabel.SetText(strconv.FormatInt(resp.TTL, 10) + "s / " + strconv.FormatInt(resp.GrantedTTL, 10) + "s")
+}
+
+// grantLease grants a new lease and offers to attach it on Put: to node's
+// own value when node is an existing key, otherwise to a new key created
+// under node.
+func (t *Tab) grantLease(node *Node) {
+	client := t.ec.getClient()
+	if client == nil {
+		return
+	}
+	if createGrantLeaseDialog().Run() != walk.DlgCmdOK {
+		return
+	}
+	resp, err := client.Grant(context.Background(), grantedTTL)
+	if err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", "Grant lease failed.", walk.MsgBoxIconError)
+		return
+	}
+
+	if node.isLeaf() {
+		t.attachLeaseToExistingKey(node, resp.ID)
+		return
+	}
+	t.attachLeaseToNewKey(node, resp.ID)
+}
+
+// attachLeaseToExistingKey re-Puts node's current value under lease, so the
+// existing key keeps its value but inherits the lease's TTL.
+func (t *Tab) attachLeaseToExistingKey(node *Node, leaseID clientv3.LeaseID) {
+	client := t.ec.getClient()
+	if client == nil {
+		return
+	}
+	if walk.MsgBox(mainWindow, "Lease Granted",
+		"Lease "+strconv.FormatInt(int64(leaseID), 16)+" granted with TTL "+strconv.FormatInt(grantedTTL, 10)+"s.\n\nAttach it to "+node.key+" now?",
+		walk.MsgBoxYesNo|walk.MsgBoxIconInformation) != walk.DlgCmdYes {
+		return
+	}
+	resp, err := client.Get(context.Background(), node.key)
+	if err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", "Get failed.", walk.MsgBoxIconError)
+		return
+	}
+	var value string
+	if len(resp.Kvs) > 0 {
+		value = string(resp.Kvs[0].Value)
+	}
+	if _, err := client.Put(context.Background(), node.key, value, clientv3.WithLease(leaseID)); err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", "Attach lease failed.", walk.MsgBoxIconError)
+		return
+	}
+	if t.currentNode == node {
+		t.showLeaseInfo(int64(leaseID))
+	}
+}
+
+// attachLeaseToNewKey creates a new key under node with an empty value and
+// the given lease attached.
+func (t *Tab) attachLeaseToNewKey(node *Node, leaseID clientv3.LeaseID) {
+	client := t.ec.getClient()
+	if client == nil {
+		return
+	}
+	if walk.MsgBox(mainWindow, "Lease Granted",
+		"Lease "+strconv.FormatInt(int64(leaseID), 16)+" granted with TTL "+strconv.FormatInt(grantedTTL, 10)+"s.\n\nAttach a new key to it now?",
+		walk.MsgBoxYesNo|walk.MsgBoxIconInformation) != walk.DlgCmdYes {
+		return
+	}
+	if createKeyNameDialog("New Key", "").Run() != walk.DlgCmdOK {
+		return
+	}
+	prefix := strings.TrimRight(node.prefix(), "/")
+	newKey := prefix + "/" + strings.TrimPrefix(keyNameInput, "/")
+	if _, err := client.Put(context.Background(), newKey, "", clientv3.WithLease(leaseID)); err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", "Create failed.", walk.MsgBoxIconError)
+		return
+	}
+	keys := relativeKeys(node.prefix(), newKey)
+	node.addNode(keys, newKey, t.ec.Name)
+	t.treeModel.PublishItemsReset(node)
+}
+
+func (t *Tab) appendWatchLog(line string) {
+	if t.watchLog != nil {
+		t.watchLog.AppendText(line + "\r\n")
+	}
+}
+
+func (t *Tab) startWatch(node *Node) {
+	client := t.ec.getClient()
+	if client == nil || t.watchCancels[node] != nil {
+		return
+	}
+	prefix := strings.TrimRight(node.prefix(), "/") + "/"
+	ctx, cancel := context.WithCancel(context.Background())
+	t.watchCancels[node] = cancel
+	watchChan := client.Watch(ctx, prefix, clientv3.WithPrefix())
+	t.appendWatchLog("[watch] started on " + prefix)
+	go func() {
+		for resp := range watchChan {
+			resp := resp
+			mainWindow.Synchronize(func() {
+				for _, ev := range resp.Events {
+					t.appendWatchLog("[" + ev.Type.String() + "] " + string(ev.Kv.Key))
+					keys := relativeKeys(prefix, string(ev.Kv.Key))
+					t.treeMu.Lock()
+					switch ev.Type {
+					case clientv3.EventTypePut:
+						node.addNode(keys, string(ev.Kv.Key), t.ec.Name)
+					case clientv3.EventTypeDelete:
+						if target := node.findNode(keys); target != nil && target.parent != nil {
+							target.parent.removeChild(target)
+						}
+					}
+					t.treeMu.Unlock()
+					t.treeModel.PublishItemsReset(node)
+				}
+			})
+		}
+	}()
+}
+
+func (t *Tab) stopWatch(node *Node) {
+	if cancel := t.watchCancels[node]; cancel != nil {
+		cancel()
+		delete(t.watchCancels, node)
+		t.appendWatchLog("[watch] stopped on " + node.key)
+	}
+}
+
+// exportSubtree writes every key under node's prefix to a JSON file the
+// user picks, using the same KVRecord shape as exportCurrentConnection.
+func (t *Tab) exportSubtree(node *Node) {
+	if t.ec.getClient() == nil {
+		return
+	}
+	dlg := new(walk.FileDialog)
+	dlg.Filter = "JSON files (*.json)|*.json"
+	dlg.Title = "Export Subtree as JSON"
+	ok, err := dlg.ShowSave(mainWindow)
+	if err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", err.Error(), walk.MsgBoxIconError)
+		return
+	}
+	if !ok {
+		return
+	}
+	path := dlg.FilePath
+	if filepath.Ext(path) == "" {
+		path += ".json"
+	}
+	count, err := exportKeyspace(t.ec, strings.TrimRight(node.prefix(), "/")+"/", path)
+	if err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", err.Error(), walk.MsgBoxIconError)
+		return
+	}
+	walk.MsgBox(mainWindow, "Export complete", fmt.Sprintf("Exported %d keys to %s", count, path), walk.MsgBoxIconInformation)
+}
+
+func (t *Tab) saveValue(node *Node) {
+	client := t.ec.getClient()
+	if node == nil || node.key == "" || client == nil {
+		return
+	}
+	if _, err := client.Put(context.Background(), node.key, t.valueEdit.Text()); err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", "Save failed.", walk.MsgBoxIconError)
+		return
+	}
+	t.setValueDirty(false)
+}
+
+func (t *Tab) createKey(node *Node) {
+	client := t.ec.getClient()
+	if client == nil {
+		return
+	}
+	if createKeyNameDialog("New Key", "").Run() != walk.DlgCmdOK {
+		return
+	}
+	prefix := strings.TrimRight(node.prefix(), "/")
+	newKey := prefix + "/" + strings.TrimPrefix(keyNameInput, "/")
+	if _, err := client.Put(context.Background(), newKey, ""); err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", "Create failed.", walk.MsgBoxIconError)
+		return
+	}
+	keys := relativeKeys(node.prefix(), newKey)
+	node.addNode(keys, newKey, t.ec.Name)
+	t.treeModel.PublishItemsReset(node)
+}
+
+func (t *Tab) renameKey(node *Node) {
+	client := t.ec.getClient()
+	if node == nil || node.key == "" || client == nil {
+		return
+	}
+	if createKeyNameDialog("Rename Key", node.key).Run() != walk.DlgCmdOK {
+		return
+	}
+	newKey := keyNameInput
+	if newKey == node.key {
+		return
+	}
+	resp, err := client.Get(context.Background(), node.key)
+	if err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", "Rename failed.", walk.MsgBoxIconError)
+		return
+	}
+	if resp == nil || len(resp.Kvs) == 0 {
+		return
+	}
+	if walk.MsgBox(mainWindow, "Confirm", "Rename \""+node.key+"\" to \""+newKey+"\"?",
+		walk.MsgBoxYesNo|walk.MsgBoxIconQuestion) != walk.DlgCmdYes {
+		return
+	}
+	if _, err := client.Put(context.Background(), newKey, string(resp.Kvs[0].Value)); err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", "Rename failed.", walk.MsgBoxIconError)
+		return
+	}
+	if _, err := client.Delete(context.Background(), node.key); err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", "Rename failed.", walk.MsgBoxIconError)
+		return
+	}
+	parent := node.parent
+	parent.removeChild(node)
+	t.treeModel.PublishItemRemoved(node)
+	keys := relativeKeys(parent.prefix(), newKey)
+	parent.addNode(keys, newKey, t.ec.Name)
+	t.treeModel.PublishItemsReset(parent)
+	t.currentNode = nil
+	t.setValueDirty(false)
+	t.keyEdit.SetText("")
+	t.valueEdit.SetText("")
+}
+
+func (t *Tab) deleteKey(node *Node) {
+	client := t.ec.getClient()
+	if node == nil || node.key == "" || client == nil {
+		return
+	}
+	if walk.MsgBox(mainWindow, "Confirm", "Delete key \""+node.key+"\"? This cannot be undone.",
+		walk.MsgBoxYesNo|walk.MsgBoxIconWarning) != walk.DlgCmdYes {
+		return
+	}
+	if _, err := client.Delete(context.Background(), node.key); err != nil {
+		zap.L().Error("Error", zap.Error(err))
+		walk.MsgBox(mainWindow, "Error", "Delete failed.", walk.MsgBoxIconError)
+		return
+	}
+	if t.currentNode == node {
+		t.currentNode = nil
+		t.setValueDirty(false)
+		t.keyEdit.SetText("")
+		t.valueEdit.SetText("")
+	}
+	if node.parent != nil {
+		node.parent.removeChild(node)
 	}
-	node.refreshNodeIcon("img/unconnected.ico")
-	treeModel.PublishItemsReset(node)
-	node.connected = false
+	t.treeModel.PublishItemRemoved(node)
 }
@@ -0,0 +1,106 @@
+package main
+
+import "testing"
+
+func TestRelativeKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		base string
+		key  string
+		want []string
+	}{
+		{"root prefix", "/", "/foo/bar", []string{"foo", "bar"}},
+		{"nested prefix", "/foo/", "/foo/bar/baz", []string{"bar", "baz"}},
+		{"prefix without trailing slash", "/foo", "/foo/bar", []string{"bar"}},
+		{"single segment", "/foo/", "/foo/bar", []string{"bar"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := relativeKeys(tt.base, tt.key)
+			if len(got) != len(tt.want) {
+				t.Fatalf("relativeKeys(%q, %q) = %v, want %v", tt.base, tt.key, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("relativeKeys(%q, %q) = %v, want %v", tt.base, tt.key, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNodePrefix(t *testing.T) {
+	root := newNode("", "", "", "conn", nil)
+	foo := newNode("foo", "/foo", "img/dir.ico", "conn", root)
+	bar := newNode("bar", "/foo/bar", "img/file.ico", "conn", foo)
+
+	if got := root.prefix(); got != "/" {
+		t.Errorf("root.prefix() = %q, want %q", got, "/")
+	}
+	if got := foo.prefix(); got != "/foo" {
+		t.Errorf("foo.prefix() = %q, want %q", got, "/foo")
+	}
+	if got := bar.prefix(); got != "/foo/bar" {
+		t.Errorf("bar.prefix() = %q, want %q", got, "/foo/bar")
+	}
+}
+
+func TestNodeAddNodeAndFindNode(t *testing.T) {
+	root := newNode("", "", "", "conn", nil)
+
+	root.addNode(relativeKeys("/", "/foo/bar"), "/foo/bar", "conn")
+	root.addNode(relativeKeys("/", "/foo/baz"), "/foo/baz", "conn")
+
+	foo := root.findNode([]string{"foo"})
+	if foo == nil {
+		t.Fatal("findNode([\"foo\"]) = nil, want the directory node created by addNode")
+	}
+	if foo.isLeaf() {
+		t.Error("foo.isLeaf() = true, want false: it groups two keys, it isn't one")
+	}
+	if got := len(foo.children); got != 2 {
+		t.Fatalf("len(foo.children) = %d, want 2", got)
+	}
+
+	bar := root.findNode([]string{"foo", "bar"})
+	if bar == nil || !bar.isLeaf() {
+		t.Fatal("findNode([\"foo\", \"bar\"]) did not return a leaf node")
+	}
+	if bar.key != "/foo/bar" {
+		t.Errorf("bar.key = %q, want %q", bar.key, "/foo/bar")
+	}
+
+	if got := root.findNode([]string{"missing"}); got != nil {
+		t.Errorf("findNode([\"missing\"]) = %v, want nil", got)
+	}
+
+	// Re-adding the same key must not create a duplicate sibling.
+	root.addNode(relativeKeys("/", "/foo/bar"), "/foo/bar", "conn")
+	if got := len(foo.children); got != 2 {
+		t.Fatalf("len(foo.children) after re-adding an existing key = %d, want 2", got)
+	}
+}
+
+func TestNodeRemoveChild(t *testing.T) {
+	root := newNode("", "", "", "conn", nil)
+	root.addNode(relativeKeys("/", "/foo/bar"), "/foo/bar", "conn")
+	root.addNode(relativeKeys("/", "/foo/baz"), "/foo/baz", "conn")
+
+	foo := root.findNode([]string{"foo"})
+	bar := root.findNode([]string{"foo", "bar"})
+
+	foo.removeChild(bar)
+
+	if got := len(foo.children); got != 1 {
+		t.Fatalf("len(foo.children) after removeChild = %d, want 1", got)
+	}
+	if got := foo.findNode([]string{"bar"}); got != nil {
+		t.Errorf("findNode([\"bar\"]) after removeChild = %v, want nil", got)
+	}
+
+	// Removing a node that isn't a child is a no-op, not a panic.
+	foo.removeChild(bar)
+	if got := len(foo.children); got != 1 {
+		t.Fatalf("len(foo.children) after redundant removeChild = %d, want 1", got)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/proxy"
+)
+
+// init registers "http" and "https" dialer types with golang.org/x/net/proxy
+// so buildClientConfig's proxy.FromURL also accepts an HTTP(S) proxy
+// (via CONNECT tunneling), not just the socks5 scheme it supports natively.
+func init() {
+	proxy.RegisterDialerType("http", newHTTPProxyDialer)
+	proxy.RegisterDialerType("https", newHTTPProxyDialer)
+}
+
+// httpProxyDialer tunnels a connection through an HTTP(S) proxy using
+// CONNECT, per RFC 7231 §4.3.6.
+type httpProxyDialer struct {
+	proxyURL *url.URL
+	forward  proxy.Dialer
+}
+
+func newHTTPProxyDialer(proxyURL *url.URL, forward proxy.Dialer) (proxy.Dialer, error) {
+	return &httpProxyDialer{proxyURL: proxyURL, forward: forward}, nil
+}
+
+func (d *httpProxyDialer) Dial(network, addr string) (net.Conn, error) {
+	conn, err := d.forward.Dial(network, d.proxyURL.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	connectReq := &http.Request{
+		Method: "CONNECT",
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if user := d.proxyURL.User; user != nil {
+		password, _ := user.Password()
+		token := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		connectReq.Header.Set("Proxy-Authorization", "Basic "+token)
+	}
+	if err := connectReq.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), connectReq)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy: CONNECT to %s via %s failed: %s", addr, d.proxyURL.Host, resp.Status)
+	}
+	return conn, nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/etcdserver/api/v3rpc/rpctypes"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ConnStatus is the health of a single connection, as tracked by its
+// supervisor goroutine (superviseConnection) and mirrored onto the
+// EtcdConfig it belongs to.
+type ConnStatus string
+
+const (
+	StatusConnected    ConnStatus = "connected"
+	StatusDegraded     ConnStatus = "degraded"
+	StatusDisconnected ConnStatus = "disconnected"
+)
+
+// StatusEvent is one health transition for a named connection. A goroutine
+// started by main drains these off statusEvents and applies them on the UI
+// goroutine via handleStatusEvent so the tree icon, status label and watch log stay in
+// lockstep with what the supervisor observes.
+type StatusEvent struct {
+	Name   string
+	Status ConnStatus
+}
+
+var statusEvents = make(chan StatusEvent, 32)
+
+const (
+	statusPollInterval  = 5 * time.Second
+	statusPollTimeout   = 3 * time.Second
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = 30 * time.Second
+)
+
+// superviseConnection polls ec's Status on an interval for as long as stop
+// stays open, and reconnects with exponential backoff whenever the poll
+// reports ErrGRPCNoLeader or a transient transport error. It exits as soon
+// as stop is closed, which disconnect does before tearing the client down.
+func superviseConnection(ec *EtcdConfig, stop <-chan struct{}) {
+	backoff := minReconnectBackoff
+	ticker := time.NewTicker(statusPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		client := ec.getClient()
+		if client == nil {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), statusPollTimeout)
+		_, err := client.Status(ctx, client.Endpoints()[0])
+		cancel()
+
+		if err == nil {
+			backoff = minReconnectBackoff
+			setConnStatus(ec, StatusConnected)
+			continue
+		}
+		zap.L().Error("Error", zap.Error(err))
+
+		if !isRecoverable(err) {
+			setConnStatus(ec, StatusDisconnected)
+			continue
+		}
+
+		setConnStatus(ec, StatusDegraded)
+		select {
+		case <-stop:
+			return
+		case <-time.After(backoff):
+		}
+
+		if err := reconnectClient(ec); err != nil {
+			zap.L().Error("Error", zap.Error(err))
+			setConnStatus(ec, StatusDisconnected)
+			backoff *= 2
+			if backoff > maxReconnectBackoff {
+				backoff = maxReconnectBackoff
+			}
+			continue
+		}
+		backoff = minReconnectBackoff
+		setConnStatus(ec, StatusConnected)
+	}
+}
+
+// isRecoverable reports whether err looks transient (no leader, unavailable
+// transport, timeout) and therefore worth a reconnect attempt, as opposed
+// to a permanent failure such as bad credentials.
+func isRecoverable(err error) bool {
+	if errors.Is(err, rpctypes.ErrGRPCNoLeader) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// reconnectClient dials a fresh client from ec's current TLS/proxy settings
+// and swaps it in, closing the old one only once the new one is up.
+func reconnectClient(ec *EtcdConfig) error {
+	cfg, err := buildClientConfig(ec)
+	if err != nil {
+		return err
+	}
+	client, err := clientv3.New(cfg)
+	if err != nil {
+		return err
+	}
+	if old := ec.setClient(client); old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// setConnStatus updates ec's status and, on an actual change, emits a
+// StatusEvent for the UI goroutine. The send is non-blocking so a slow or
+// stalled UI can never back up the supervisor loop.
+func setConnStatus(ec *EtcdConfig, s ConnStatus) {
+	if !ec.setStatus(s) {
+		return
+	}
+	select {
+	case statusEvents <- StatusEvent{Name: ec.Name, Status: s}:
+	default:
+	}
+}